@@ -2,7 +2,6 @@ package merkle
 
 import (
 	"fmt"
-	"hash"
 	"math"
 )
 
@@ -21,14 +20,43 @@ var (
 )
 
 type Config struct {
-	hasher       hash.Hash
-	depth        uint64
-	hashSize     uint64
-	allLeavesNum uint64
-	allNodesNum  uint64
+	hasher           Hasher
+	depth            uint64
+	hashSize         uint64
+	allLeavesNum     uint64
+	allNodesNum      uint64
+	storage          Storage
+	sparse           bool
+	emptyHashes      [][]byte
+	domainSeparation bool
 }
 
-func NewConfig(hasher hash.Hash, depth, hashSize uint64) (*Config, error) {
+// ConfigOption customizes a Config constructed by NewConfig.
+type ConfigOption func(*Config)
+
+// WithStorage makes the tree persist its nodes to s instead of keeping them
+// all in memory, which is what NewConfig uses by default.
+func WithStorage(s Storage) ConfigOption {
+	return func(c *Config) {
+		c.storage = s
+	}
+}
+
+// WithDomainSeparation prefixes every leaf hash and every interior hash with
+// a distinct, fixed byte (RFC 6962 style) before hashing, so that a leaf and
+// an interior node can never hash to the same value. Off by default for
+// backwards compatibility with trees built before this option existed.
+func WithDomainSeparation() ConfigOption {
+	return func(c *Config) {
+		c.domainSeparation = true
+	}
+}
+
+// NewConfig builds a Config around hasher, a Hasher implementation used to
+// compute every leaf and interior node hash in the tree. hasher must be safe
+// for concurrent use, since VerifyMembershipProof and the other Verify* APIs
+// may call it from multiple goroutines sharing this Config.
+func NewConfig(hasher Hasher, depth, hashSize uint64, opts ...ConfigOption) (*Config, error) {
 	if depth < DepthMin {
 		return nil, ErrTooSmallDepth
 	}
@@ -49,11 +77,54 @@ func NewConfig(hasher hash.Hash, depth, hashSize uint64) (*Config, error) {
 		allNodesNum += i
 	}
 
-	return &Config{
+	c := &Config{
 		hasher:       hasher,
 		depth:        depth,
 		hashSize:     hashSize,
 		allLeavesNum: allLeavesNum,
 		allNodesNum:  allNodesNum,
-	}, nil
+		storage:      newMemoryStorage(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.sparse {
+		c.computeEmptyHashes()
+	}
+
+	return c, nil
+}
+
+// leafPrefix and internalPrefix are the RFC 6962 domain-separation tags
+// applied when a Config is built with WithDomainSeparation, so that a leaf
+// hash can never collide with an interior hash for the same input.
+var (
+	leafPrefix     = []byte{0x00}
+	internalPrefix = []byte{0x01}
+)
+
+func (c *Config) hashLeaf(leaf []byte) []byte {
+	if c.domainSeparation {
+		prefixed := make([]byte, 0, len(leafPrefix)+len(leaf))
+		prefixed = append(prefixed, leafPrefix...)
+		prefixed = append(prefixed, leaf...)
+
+		return c.hasher.HashLeaf(prefixed)
+	}
+
+	return c.hasher.HashLeaf(leaf)
+}
+
+func (c *Config) hashChildren(left, right []byte) []byte {
+	if c.domainSeparation {
+		prefixed := make([]byte, 0, len(internalPrefix)+len(left))
+		prefixed = append(prefixed, internalPrefix...)
+		prefixed = append(prefixed, left...)
+
+		return c.hasher.HashChildren(prefixed, right)
+	}
+
+	return c.hasher.HashChildren(left, right)
 }
@@ -0,0 +1,80 @@
+package merkle
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Hasher computes leaf and interior node hashes for a Tree. Unlike
+// hash.Hash, which carries mutable internal state, a Hasher's methods are
+// expected to be safe to call concurrently, which is what makes
+// VerifyMembershipProof (and the other Verify* APIs) safe to call from
+// multiple goroutines against the same Config.
+type Hasher interface {
+	HashLeaf(data []byte) []byte
+	HashChildren(left, right []byte) []byte
+}
+
+// SHA256Hasher is the built-in Hasher backed by crypto/sha256.
+type SHA256Hasher struct{}
+
+func (SHA256Hasher) HashLeaf(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func (SHA256Hasher) HashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(left)
+	h.Write(right)
+
+	return h.Sum(nil)
+}
+
+// Keccak256Hasher is the built-in Hasher backed by the Keccak-256 variant of
+// SHA-3, as used by Ethereum and other chains this tree's proofs are often
+// verified against.
+type Keccak256Hasher struct{}
+
+func (Keccak256Hasher) HashLeaf(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+func (Keccak256Hasher) HashChildren(left, right []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(left)
+	h.Write(right)
+
+	return h.Sum(nil)
+}
+
+// PoseidonHasher adapts a Poseidon implementation (e.g. go-iden3-crypto's
+// poseidon.Hash) to the Hasher interface, so zk-friendly circuits can verify
+// proofs produced by this package without a SHA round trip. Hash receives
+// one field element per input chunk: a single-element slice for a leaf, two
+// elements for a pair of children.
+type PoseidonHasher struct {
+	Hash func(inputs [][]byte) ([]byte, error)
+}
+
+func (h PoseidonHasher) HashLeaf(data []byte) []byte {
+	b, err := h.Hash([][]byte{data})
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func (h PoseidonHasher) HashChildren(left, right []byte) []byte {
+	b, err := h.Hash([][]byte{left, right})
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
@@ -0,0 +1,22 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDomainSeparation(t *testing.T) {
+	config, err := NewConfig(SHA256Hasher{}, 2, 32, WithDomainSeparation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf := []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}
+
+	leafHash := config.hashLeaf(leaf)
+	childrenHash := config.hashChildren(leaf, leaf)
+
+	if bytes.Equal(leafHash, childrenHash) {
+		t.Errorf("expected leaf and interior hashes to differ under domain separation")
+	}
+}
@@ -0,0 +1,92 @@
+package merkle
+
+// SetLeaf replaces the leaf at index and recomputes only the path of
+// ancestor hashes up to the root, instead of rebuilding the whole tree. It
+// returns the new root.
+func (t *Tree) SetLeaf(index int, leaf []byte) (*Node, error) {
+	if t.config.sparse {
+		return nil, ErrSparseMode
+	}
+	if index < 0 || uint64(index) >= t.config.allLeavesNum {
+		return nil, ErrLeafIndexOutOfRange
+	}
+
+	node := &Node{b: t.config.hashLeaf(leaf)}
+	if err := t.putNode(0, uint64(index), node); err != nil {
+		return nil, err
+	}
+
+	return t.recomputePath(uint64(index))
+}
+
+// AppendLeaf adds leaf as the next unused leaf slot and recomputes the path
+// of ancestor hashes up to the root. It returns the index the leaf was
+// assigned to and the new root.
+func (t *Tree) AppendLeaf(leaf []byte) (int, *Node, error) {
+	if t.config.sparse {
+		return 0, nil, ErrSparseMode
+	}
+	if t.leavesNum >= t.config.allLeavesNum {
+		return 0, nil, ErrTooManyLeaves
+	}
+
+	index := t.leavesNum
+
+	node := &Node{b: t.config.hashLeaf(leaf)}
+	if err := t.putNode(0, index, node); err != nil {
+		return 0, nil, err
+	}
+
+	t.leavesNum++
+
+	root, err := t.recomputePath(index)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return int(index), root, nil
+}
+
+// recomputePath walks from the leaf at index up to the root, re-hashing
+// each ancestor from its two children, and persists the O(depth) nodes that
+// changed.
+func (t *Tree) recomputePath(index uint64) (*Node, error) {
+	var last *Node
+
+	for level, idx := uint64(0), index; level < t.config.depth; level, idx = level+1, idx/2 {
+		siblingIdx := idx ^ 1
+
+		self, err := t.getNode(level, idx)
+		if err != nil {
+			return nil, err
+		}
+
+		sibling, err := t.getNode(level, siblingIdx)
+		if err != nil {
+			return nil, err
+		}
+
+		var left, right *Node
+		if idx%2 == 0 {
+			left, right = self, sibling
+		} else {
+			left, right = sibling, self
+		}
+
+		parent := &Node{
+			b:     t.config.hashChildren(left.b, right.b),
+			left:  left,
+			right: right,
+		}
+
+		if err := t.putNode(level+1, idx/2, parent); err != nil {
+			return nil, err
+		}
+
+		last = parent
+	}
+
+	t.root = last
+
+	return last, nil
+}
@@ -0,0 +1,84 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetLeaf(t *testing.T) {
+	tree, err := NewTree(
+		newTestConfig(),
+		[][]byte{
+			[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+			[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+			[]byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootBefore := tree.Root()
+
+	root, err := tree.SetLeaf(0, []byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(root.b, rootBefore.b) {
+		t.Errorf("expected root to change")
+	}
+
+	if _, err := tree.SetLeaf(4, []byte{0x00}); err != ErrLeafIndexOutOfRange {
+		t.Errorf("expected: %v, actual: %v", ErrLeafIndexOutOfRange, err)
+	}
+
+	sparseConfig, err := NewConfig(SHA256Hasher{}, 2, 32, WithSparseMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sparseTree, err := NewTree(sparseConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sparseTree.SetLeaf(0, []byte{0x00}); err != ErrSparseMode {
+		t.Errorf("expected: %v, actual: %v", ErrSparseMode, err)
+	}
+}
+
+func TestAppendLeaf(t *testing.T) {
+	tree, err := NewTree(
+		newTestConfig(),
+		[][]byte{
+			[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+			[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+			[]byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, _, err := tree.AppendLeaf([]byte{0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 3 {
+		t.Errorf("expected: %d, actual: %d", 3, index)
+	}
+
+	if _, _, err := tree.AppendLeaf([]byte{0x05}); err != ErrTooManyLeaves {
+		t.Errorf("expected: %v, actual: %v", ErrTooManyLeaves, err)
+	}
+
+	sparseConfig, err := NewConfig(SHA256Hasher{}, 2, 32, WithSparseMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sparseTree, err := NewTree(sparseConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sparseTree.AppendLeaf([]byte{0x00}); err != ErrSparseMode {
+		t.Errorf("expected: %v, actual: %v", ErrSparseMode, err)
+	}
+}
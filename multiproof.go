@@ -0,0 +1,208 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+var (
+	// ErrDuplicateLeafIndex is returned when the same leaf index appears
+	// more than once in a multi-proof request.
+	ErrDuplicateLeafIndex = fmt.Errorf("duplicate leaf index")
+	// ErrMismatchedLeaves is returned when indices and leaves passed to
+	// VerifyMultiProof have different lengths.
+	ErrMismatchedLeaves = fmt.Errorf("indices and leaves must have the same length")
+	// ErrInvalidMultiProof is returned when a multi-proof cannot be parsed
+	// or does not match the indices it is being verified against. Leaves
+	// that do not hash up to the root make VerifyMultiProof return false
+	// without this error.
+	ErrInvalidMultiProof = fmt.Errorf("invalid multi proof")
+)
+
+// CreateMultiProof builds a single proof that covers every leaf in indices.
+// It walks the tree bottom-up, and at each level emits only the sibling
+// hashes of nodes on the covered paths that cannot be derived from other
+// covered nodes, in ascending index order.
+func (t *Tree) CreateMultiProof(indices []int) ([]byte, error) {
+	sortedIndices, err := t.sortedUniqueIndices(indices)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[uint64]bool, len(sortedIndices))
+	for _, idx := range sortedIndices {
+		present[uint64(idx)] = true
+	}
+
+	var siblingHashes [][]byte
+
+	for level := uint64(0); level < t.config.depth; level++ {
+		idxs := make([]uint64, 0, len(present))
+		for idx := range present {
+			idxs = append(idxs, idx)
+		}
+		sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+
+		next := make(map[uint64]bool, len(idxs))
+		for _, idx := range idxs {
+			siblingIdx := idx ^ 1
+			if !present[siblingIdx] {
+				node, err := t.getNode(level, siblingIdx)
+				if err != nil {
+					return nil, err
+				}
+				siblingHashes = append(siblingHashes, node.b)
+			}
+			next[idx/2] = true
+		}
+
+		present = next
+	}
+
+	buf := new(bytes.Buffer)
+	writeUvarint(buf, uint64(len(sortedIndices)))
+	for _, idx := range sortedIndices {
+		writeUvarint(buf, uint64(idx))
+	}
+	writeUvarint(buf, uint64(len(siblingHashes)))
+	for _, h := range siblingHashes {
+		buf.Write(h)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// VerifyMultiProof checks that leaves, at the positions given by indices,
+// are all included in the tree rooted at t.Root(), using proof as produced
+// by CreateMultiProof.
+func (t *Tree) VerifyMultiProof(indices []int, leaves [][]byte, proof []byte) (bool, error) {
+	if len(indices) != len(leaves) {
+		return false, ErrMismatchedLeaves
+	}
+
+	sortedIndices, err := t.sortedUniqueIndices(indices)
+	if err != nil {
+		return false, err
+	}
+
+	leafByIndex := make(map[uint64][]byte, len(indices))
+	for i, idx := range indices {
+		leafByIndex[uint64(idx)] = leaves[i]
+	}
+
+	r := bytes.NewReader(proof)
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return false, ErrInvalidMultiProof
+	}
+	if int(n) != len(sortedIndices) {
+		return false, ErrInvalidMultiProof
+	}
+
+	proofIndices := make([]uint64, n)
+	for i := range proofIndices {
+		idx, err := binary.ReadUvarint(r)
+		if err != nil {
+			return false, ErrInvalidMultiProof
+		}
+		proofIndices[i] = idx
+	}
+	for i, idx := range sortedIndices {
+		if proofIndices[i] != uint64(idx) {
+			return false, ErrInvalidMultiProof
+		}
+	}
+
+	nHashes, err := binary.ReadUvarint(r)
+	if err != nil {
+		return false, ErrInvalidMultiProof
+	}
+
+	siblingHashes := make([][]byte, nHashes)
+	for i := range siblingHashes {
+		h := make([]byte, t.config.hashSize)
+		if _, err := io.ReadFull(r, h); err != nil {
+			return false, ErrInvalidMultiProof
+		}
+		siblingHashes[i] = h
+	}
+
+	known := make(map[uint64][]byte, len(leafByIndex))
+	for idx, leaf := range leafByIndex {
+		known[idx] = t.config.hashLeaf(leaf)
+	}
+
+	for level := uint64(0); level < t.config.depth; level++ {
+		idxs := make([]uint64, 0, len(known))
+		for idx := range known {
+			idxs = append(idxs, idx)
+		}
+		sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+
+		next := make(map[uint64][]byte, len(idxs))
+		visited := make(map[uint64]bool, len(idxs))
+		for _, idx := range idxs {
+			if visited[idx] {
+				continue
+			}
+			visited[idx] = true
+
+			siblingIdx := idx ^ 1
+			sibling, ok := known[siblingIdx]
+			if !ok {
+				if len(siblingHashes) == 0 {
+					return false, ErrInvalidMultiProof
+				}
+				sibling, siblingHashes = siblingHashes[0], siblingHashes[1:]
+			} else {
+				visited[siblingIdx] = true
+			}
+
+			var left, right []byte
+			if idx%2 == 0 {
+				left, right = known[idx], sibling
+			} else {
+				left, right = sibling, known[idx]
+			}
+
+			next[idx/2] = t.config.hashChildren(left, right)
+		}
+
+		known = next
+	}
+
+	if len(known) != 1 {
+		return false, ErrInvalidMultiProof
+	}
+
+	return bytes.Equal(known[0], t.Root().b), nil
+}
+
+// sortedUniqueIndices validates indices against the tree's bounds and
+// returns them sorted, or an error if any index is out of range or
+// duplicated.
+func (t *Tree) sortedUniqueIndices(indices []int) ([]int, error) {
+	sorted := append([]int{}, indices...)
+	sort.Ints(sorted)
+
+	for i, idx := range sorted {
+		if idx < 0 || uint64(idx) >= t.config.allLeavesNum {
+			return nil, ErrLeafIndexOutOfRange
+		}
+		if i > 0 && sorted[i] == sorted[i-1] {
+			return nil, ErrDuplicateLeafIndex
+		}
+	}
+
+	return sorted, nil
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
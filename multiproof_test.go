@@ -0,0 +1,50 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestMultiProof(t *testing.T) {
+	leaves := [][]byte{
+		[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+		[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+		[]byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+	}
+
+	tree, err := NewTree(newTestConfig(), leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indices := []int{0, 2}
+	proof, err := tree.CreateMultiProof(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := tree.VerifyMultiProof(indices, [][]byte{leaves[0], leaves[2]}, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("expected: %t, actual: %t", true, ok)
+	}
+
+	ok, err = tree.VerifyMultiProof(indices, [][]byte{leaves[1], leaves[2]}, proof)
+	if err == nil && ok {
+		t.Errorf("expected verification to fail for wrong leaves")
+	}
+
+	if _, err := tree.CreateMultiProof([]int{0, 0}); err != ErrDuplicateLeafIndex {
+		t.Errorf("expected: %v, actual: %v", ErrDuplicateLeafIndex, err)
+	}
+
+	if _, err := tree.CreateMultiProof([]int{0, 4}); err != ErrLeafIndexOutOfRange {
+		t.Errorf("expected: %v, actual: %v", ErrLeafIndexOutOfRange, err)
+	}
+
+	truncated := proof[:len(proof)-1]
+	if _, err := tree.VerifyMultiProof(indices, [][]byte{leaves[0], leaves[2]}, truncated); err != ErrInvalidMultiProof {
+		t.Errorf("expected: %v, actual: %v", ErrInvalidMultiProof, err)
+	}
+}
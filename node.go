@@ -0,0 +1,40 @@
+package merkle
+
+// Node is a single node in the tree, holding its hash and, where they were
+// attached, pointers to its two children. Only the root returned by NewTree
+// (outside sparse mode) or by an update carries child pointers, and only to
+// its immediate children; nodes loaded from Storage carry none.
+type Node struct {
+	b     []byte
+	left  *Node
+	right *Node
+}
+
+// B returns the node's hash.
+func (n *Node) B() []byte {
+	if n == nil {
+		return nil
+	}
+
+	return n.b
+}
+
+// Left returns the node's left child, or nil if n has no child pointers
+// attached (always the case for a leaf).
+func (n *Node) Left() *Node {
+	if n == nil {
+		return nil
+	}
+
+	return n.left
+}
+
+// Right returns the node's right child, or nil if n has no child pointers
+// attached (always the case for a leaf).
+func (n *Node) Right() *Node {
+	if n == nil {
+		return nil
+	}
+
+	return n.right
+}
@@ -0,0 +1,21 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testNodesEquality(t *testing.T, expected, actual *Node) {
+	t.Helper()
+
+	if expected == nil || actual == nil {
+		if expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		return
+	}
+
+	if !bytes.Equal(expected.b, actual.b) {
+		t.Errorf("expected: %x, actual: %x", expected.b, actual.b)
+	}
+}
@@ -0,0 +1,201 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// ErrInvalidPartialTree is returned when a partial-tree proof is malformed
+// or inconsistent with the totalLeaves/root it is being verified against.
+var ErrInvalidPartialTree = fmt.Errorf("invalid partial tree proof")
+
+// CreatePartialTree encodes the leaves in matched, plus the minimum set of
+// interior hashes needed to reconstruct the root, as a single proof. It
+// visits the tree in pre-order and emits one flag bit per node: 1 means the
+// node is an ancestor of a matched leaf and the traversal descends into its
+// children, 0 means the node's stored hash is emitted as-is. A leaf is never
+// descended into: its hash is emitted whatever its flag, and a 1 marks it as
+// matched. This is
+// strictly more efficient than N independent membership proofs whenever the
+// matches share ancestors.
+func (t *Tree) CreatePartialTree(matched []int) ([]byte, error) {
+	containsMatch := make([]map[uint64]bool, t.config.depth+1)
+	for level := range containsMatch {
+		containsMatch[level] = map[uint64]bool{}
+	}
+	for _, idx := range matched {
+		if idx < 0 || uint64(idx) >= t.config.allLeavesNum {
+			return nil, ErrLeafIndexOutOfRange
+		}
+		containsMatch[0][uint64(idx)] = true
+	}
+	for level := uint64(1); level <= t.config.depth; level++ {
+		for idx := range containsMatch[level-1] {
+			containsMatch[level][idx/2] = true
+		}
+	}
+
+	var flagBits []bool
+	var hashes [][]byte
+
+	var visit func(level, index uint64) error
+	visit = func(level, index uint64) error {
+		match := containsMatch[level][index]
+		flagBits = append(flagBits, match)
+
+		if level == 0 || !match {
+			node, err := t.getNode(level, index)
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, node.b)
+			return nil
+		}
+
+		if err := visit(level-1, index*2); err != nil {
+			return err
+		}
+		return visit(level-1, index*2+1)
+	}
+
+	if err := visit(t.config.depth, 0); err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	writeUvarint(buf, t.config.allLeavesNum)
+	writeUvarint(buf, uint64(len(flagBits)))
+	buf.Write(packBits(flagBits))
+	writeUvarint(buf, uint64(len(hashes)))
+	for _, h := range hashes {
+		buf.Write(h)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// VerifyPartialTree verifies a proof produced by CreatePartialTree against
+// root and totalLeaves, and returns the matched leaf indices and hashes it
+// contains. config must be the Config that built the tree the proof was
+// created from, since its Hasher and domain-separation setting determine how
+// sibling hashes are recombined into their parent.
+func VerifyPartialTree(config *Config, root []byte, totalLeaves int, proof []byte) ([]int, [][]byte, error) {
+	if totalLeaves <= 0 {
+		return nil, nil, ErrInvalidPartialTree
+	}
+
+	depth := uint64(bits.Len64(uint64(totalLeaves)) - 1)
+	if uint64(1)<<depth != uint64(totalLeaves) {
+		return nil, nil, ErrInvalidPartialTree
+	}
+
+	r := bytes.NewReader(proof)
+
+	total, err := binary.ReadUvarint(r)
+	if err != nil || total != uint64(totalLeaves) {
+		return nil, nil, ErrInvalidPartialTree
+	}
+
+	nBits, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, nil, ErrInvalidPartialTree
+	}
+	flagBytes := make([]byte, (nBits+7)/8)
+	if _, err := io.ReadFull(r, flagBytes); err != nil {
+		return nil, nil, ErrInvalidPartialTree
+	}
+	flagBits := unpackBits(flagBytes, int(nBits))
+
+	nHashes, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, nil, ErrInvalidPartialTree
+	}
+	hashSize := int(config.hashSize)
+	hashes := make([][]byte, nHashes)
+	for i := range hashes {
+		h := make([]byte, hashSize)
+		if _, err := io.ReadFull(r, h); err != nil {
+			return nil, nil, ErrInvalidPartialTree
+		}
+		hashes[i] = h
+	}
+
+	var matchedIndices []int
+	var matchedHashes [][]byte
+
+	bitPos, hashPos := 0, 0
+
+	var traverse func(level, index uint64) ([]byte, error)
+	traverse = func(level, index uint64) ([]byte, error) {
+		if bitPos >= len(flagBits) {
+			return nil, ErrInvalidPartialTree
+		}
+		match := flagBits[bitPos]
+		bitPos++
+
+		if level == 0 || !match {
+			if hashPos >= len(hashes) {
+				return nil, ErrInvalidPartialTree
+			}
+			h := hashes[hashPos]
+			hashPos++
+
+			if level == 0 && match {
+				matchedIndices = append(matchedIndices, int(index))
+				matchedHashes = append(matchedHashes, h)
+			}
+
+			return h, nil
+		}
+
+		left, err := traverse(level-1, index*2)
+		if err != nil {
+			return nil, err
+		}
+		right, err := traverse(level-1, index*2+1)
+		if err != nil {
+			return nil, err
+		}
+
+		return config.hashChildren(left, right), nil
+	}
+
+	computedRoot, err := traverse(depth, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bitPos != len(flagBits) || hashPos != len(hashes) {
+		return nil, nil, ErrInvalidPartialTree
+	}
+	if !bytes.Equal(computedRoot, root) {
+		return nil, nil, ErrInvalidPartialTree
+	}
+
+	return matchedIndices, matchedHashes, nil
+}
+
+// packBits packs flagBits into bytes, LSB first, matching the well-known
+// partial-merkle-tree bit-flag encoding.
+func packBits(flagBits []bool) []byte {
+	out := make([]byte, (len(flagBits)+7)/8)
+	for i, b := range flagBits {
+		if b {
+			out[i/8] |= 1 << uint(i%8)
+		}
+	}
+
+	return out
+}
+
+// unpackBits is the inverse of packBits.
+func unpackBits(data []byte, n int) []bool {
+	out := make([]bool, n)
+	for i := 0; i < n; i++ {
+		out[i] = data[i/8]&(1<<uint(i%8)) != 0
+	}
+
+	return out
+}
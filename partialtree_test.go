@@ -0,0 +1,65 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestPartialTree(t *testing.T) {
+	leaves := [][]byte{
+		[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+		[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+		[]byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+	}
+
+	tree, err := NewTree(newTestConfig(), leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := tree.CreatePartialTree([]int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indices, hashes, err := VerifyPartialTree(newTestConfig(), tree.Root().b, 4, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) != 1 || indices[0] != 0 {
+		t.Errorf("expected matched indices: %v, actual: %v", []int{0}, indices)
+	}
+	if len(hashes) != 1 {
+		t.Errorf("expected 1 matched hash, actual: %d", len(hashes))
+	}
+}
+
+func TestPartialTreeWithDomainSeparation(t *testing.T) {
+	config, err := NewConfig(SHA256Hasher{}, 2, 32, WithDomainSeparation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaves := [][]byte{
+		[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+		[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+		[]byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+	}
+
+	tree, err := NewTree(config, leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := tree.CreatePartialTree([]int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indices, _, err := VerifyPartialTree(config, tree.Root().b, 4, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) != 1 || indices[0] != 0 {
+		t.Errorf("expected matched indices: %v, actual: %v", []int{0}, indices)
+	}
+}
@@ -0,0 +1,139 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+)
+
+var (
+	// ErrNotSparseMode is returned by sparse-only APIs when called on a
+	// Config/Tree that was not built with WithSparseMode.
+	ErrNotSparseMode = fmt.Errorf("tree is not in sparse mode")
+	// ErrSparseMode is returned by dense-index APIs when called on a
+	// Config/Tree that was built with WithSparseMode.
+	ErrSparseMode = fmt.Errorf("tree is in sparse mode")
+	// ErrKeyExists is returned by CreateNonMembershipProof when the slot
+	// derived from key is not empty.
+	ErrKeyExists = fmt.Errorf("key is already present")
+	// ErrInvalidNonMembershipProof is returned when a non-membership proof
+	// has the wrong shape to have been produced by CreateNonMembershipProof.
+	ErrInvalidNonMembershipProof = fmt.Errorf("invalid non membership proof")
+	// ErrSparseLeavesNotEmpty is returned by NewTree when called with a
+	// sparse Config and a non-empty leaves argument, since sparse slots are
+	// addressed by key through SetLeafAt rather than by dense index.
+	ErrSparseLeavesNotEmpty = fmt.Errorf("leaves must be empty in sparse mode")
+)
+
+// WithSparseMode switches the tree into sparse mode: leaves are addressed by
+// an arbitrary key, hashed down to `depth` bits, rather than by a dense
+// index, and unset slots are represented by precomputed "empty subtree"
+// hashes instead of being materialized in Storage.
+func WithSparseMode() ConfigOption {
+	return func(c *Config) {
+		c.sparse = true
+	}
+}
+
+// computeEmptyHashes precomputes the hash of an empty subtree at every
+// level, from the empty leaf up to the root, so that an unset slot never
+// needs to be written to Storage.
+func (c *Config) computeEmptyHashes() {
+	c.emptyHashes = make([][]byte, c.depth+1)
+	c.emptyHashes[0] = c.hashLeaf(make([]byte, c.hashSize))
+
+	for level := uint64(1); level <= c.depth; level++ {
+		c.emptyHashes[level] = c.hashChildren(c.emptyHashes[level-1], c.emptyHashes[level-1])
+	}
+}
+
+// keyIndex derives the leaf slot for key by hashing it, reading the first 8
+// bytes of the hash as a big-endian integer, and taking that integer's low
+// `depth` bits.
+func (t *Tree) keyIndex(key []byte) uint64 {
+	h := t.config.hashLeaf(key)
+
+	var v uint64
+	for i := 0; i < 8 && i < len(h); i++ {
+		v = v<<8 | uint64(h[i])
+	}
+
+	return v & (uint64(1)<<t.config.depth - 1)
+}
+
+// SetLeafAt sets the slot derived from key to value, recomputing only the
+// path of ancestor hashes up to the root.
+func (t *Tree) SetLeafAt(key, value []byte) (*Node, error) {
+	if !t.config.sparse {
+		return nil, ErrNotSparseMode
+	}
+
+	index := t.keyIndex(key)
+
+	if err := t.putNode(0, index, &Node{b: t.config.hashLeaf(value)}); err != nil {
+		return nil, err
+	}
+
+	return t.recomputePath(index)
+}
+
+// CreateNonMembershipProof proves that the slot derived from key is empty,
+// by supplying the sibling path from that slot up to the root.
+func (t *Tree) CreateNonMembershipProof(key []byte) ([]byte, error) {
+	if !t.config.sparse {
+		return nil, ErrNotSparseMode
+	}
+
+	index := t.keyIndex(key)
+
+	leaf, err := t.getNode(0, index)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(leaf.b, t.config.emptyHashes[0]) {
+		return nil, ErrKeyExists
+	}
+
+	proof := make([]byte, 0, t.config.depth*t.config.hashSize)
+	idx := index
+	for level := uint64(0); level < t.config.depth; level++ {
+		sibling, err := t.getNode(level, idx^1)
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, sibling.b...)
+		idx /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifyNonMembershipProof checks that the slot derived from key is empty in
+// the tree rooted at t.Root(), using proof as produced by
+// CreateNonMembershipProof.
+func (t *Tree) VerifyNonMembershipProof(key, proof []byte) (bool, error) {
+	if !t.config.sparse {
+		return false, ErrNotSparseMode
+	}
+	if uint64(len(proof)) != t.config.depth*t.config.hashSize {
+		return false, ErrInvalidNonMembershipProof
+	}
+
+	cur := t.config.emptyHashes[0]
+	idx := t.keyIndex(key)
+
+	for level := uint64(0); level < t.config.depth; level++ {
+		sibling := proof[level*t.config.hashSize : (level+1)*t.config.hashSize]
+
+		var left, right []byte
+		if idx%2 == 0 {
+			left, right = cur, sibling
+		} else {
+			left, right = sibling, cur
+		}
+		cur = t.config.hashChildren(left, right)
+
+		idx /= 2
+	}
+
+	return bytes.Equal(cur, t.Root().b), nil
+}
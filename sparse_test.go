@@ -0,0 +1,40 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestSparseNonMembershipProof(t *testing.T) {
+	config, err := NewConfig(SHA256Hasher{}, 2, 32, WithSparseMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := NewTree(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := []byte("unset-key")
+
+	proof, err := tree.CreateNonMembershipProof(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := tree.VerifyNonMembershipProof(key, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("expected: %t, actual: %t", true, ok)
+	}
+
+	if _, err := tree.SetLeafAt(key, []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tree.CreateNonMembershipProof(key); err != ErrKeyExists {
+		t.Errorf("expected: %v, actual: %v", ErrKeyExists, err)
+	}
+}
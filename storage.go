@@ -0,0 +1,141 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// ErrNodeNotFound is returned by a Storage when no value is stored for the
+// requested key.
+var ErrNodeNotFound = fmt.Errorf("node not found")
+
+// Storage abstracts the key-value store a Tree uses to persist its nodes.
+// Keys are produced by nodeKey and are stable across processes, which lets a
+// tree of depth up to DepthMax be built and queried without holding every
+// node in memory at once.
+type Storage interface {
+	Get(key []byte) ([]byte, error)
+	Put(key []byte, value []byte) error
+	Delete(key []byte) error
+	NewBatch() Batch
+}
+
+// Batch accumulates writes so that NewTree issues one round-trip to the
+// underlying store per newTreeBatchSize nodes instead of one per node.
+// SetLeaf, AppendLeaf and SetLeafAt write their nodes one at a time through
+// Put.
+type Batch interface {
+	Put(key []byte, value []byte)
+	Delete(key []byte)
+	Write() error
+}
+
+// getNode loads the node at (level, index) from the tree's Storage.
+func (t *Tree) getNode(level, index uint64) (*Node, error) {
+	b, err := t.config.storage.Get(nodeKey(level, index))
+	if err == ErrNodeNotFound && t.config.sparse {
+		return &Node{b: t.config.emptyHashes[level]}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{b: b}, nil
+}
+
+// putNode persists the node at (level, index) to the tree's Storage.
+func (t *Tree) putNode(level, index uint64, node *Node) error {
+	return t.config.storage.Put(nodeKey(level, index), node.b)
+}
+
+// nodeKey encodes a node's position in the tree as level || index, so that
+// Storage implementations backed by an ordered KV store keep nodes of the
+// same level contiguous.
+func nodeKey(level, index uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], level)
+	binary.BigEndian.PutUint64(key[8:], index)
+	return key
+}
+
+// memoryStorage is the default Storage, backed by an in-memory map. It is
+// used when a Config is created without an explicit Storage.
+type memoryStorage struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		data: map[string][]byte{},
+	}
+}
+
+func (s *memoryStorage) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.data[string(key)]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+
+	return v, nil
+}
+
+func (s *memoryStorage) Put(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[string(key)] = value
+
+	return nil
+}
+
+func (s *memoryStorage) Delete(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, string(key))
+
+	return nil
+}
+
+func (s *memoryStorage) NewBatch() Batch {
+	return &memoryBatch{s: s}
+}
+
+type memoryBatchOp struct {
+	key   []byte
+	value []byte
+	del   bool
+}
+
+type memoryBatch struct {
+	s   *memoryStorage
+	ops []memoryBatchOp
+}
+
+func (b *memoryBatch) Put(key, value []byte) {
+	b.ops = append(b.ops, memoryBatchOp{key: key, value: value})
+}
+
+func (b *memoryBatch) Delete(key []byte) {
+	b.ops = append(b.ops, memoryBatchOp{key: key, del: true})
+}
+
+func (b *memoryBatch) Write() error {
+	b.s.mu.Lock()
+	defer b.s.mu.Unlock()
+
+	for _, op := range b.ops {
+		if op.del {
+			delete(b.s.data, string(op.key))
+			continue
+		}
+		b.s.data[string(op.key)] = op.value
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package merkle
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// LevelDBStorage is a Storage backed by a LevelDB database, for trees too
+// large to keep fully in memory.
+type LevelDBStorage struct {
+	db *leveldb.DB
+}
+
+// NewLevelDBStorage opens (or creates) a LevelDB database at path and wraps
+// it as a Storage.
+func NewLevelDBStorage(path string) (*LevelDBStorage, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LevelDBStorage{db: db}, nil
+}
+
+func (s *LevelDBStorage) Get(key []byte) ([]byte, error) {
+	v, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (s *LevelDBStorage) Put(key, value []byte) error {
+	return s.db.Put(key, value, nil)
+}
+
+func (s *LevelDBStorage) Delete(key []byte) error {
+	return s.db.Delete(key, nil)
+}
+
+func (s *LevelDBStorage) NewBatch() Batch {
+	return &levelDBBatch{db: s.db, batch: new(leveldb.Batch)}
+}
+
+// Close releases the underlying LevelDB database.
+func (s *LevelDBStorage) Close() error {
+	return s.db.Close()
+}
+
+type levelDBBatch struct {
+	db    *leveldb.DB
+	batch *leveldb.Batch
+}
+
+func (b *levelDBBatch) Put(key, value []byte) {
+	b.batch.Put(key, value)
+}
+
+func (b *levelDBBatch) Delete(key []byte) {
+	b.batch.Delete(key)
+}
+
+func (b *levelDBBatch) Write() error {
+	return b.db.Write(b.batch, nil)
+}
@@ -0,0 +1,103 @@
+package merkle
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func testStorage(t *testing.T, s Storage) {
+	t.Helper()
+
+	key, value := []byte("key"), []byte("value")
+
+	if _, err := s.Get(key); err != ErrNodeNotFound {
+		t.Errorf("expected: %v, actual: %v", ErrNodeNotFound, err)
+	}
+
+	if err := s.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, value) {
+		t.Errorf("expected: %x, actual: %x", value, got)
+	}
+
+	if err := s.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(key); err != ErrNodeNotFound {
+		t.Errorf("expected: %v, actual: %v", ErrNodeNotFound, err)
+	}
+
+	batchKey1, batchKey2 := []byte("batch1"), []byte("batch2")
+
+	batch := s.NewBatch()
+	batch.Put(batchKey1, value)
+	batch.Put(batchKey2, value)
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range [][]byte{batchKey1, batchKey2} {
+		got, err := s.Get(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Errorf("expected: %x, actual: %x", value, got)
+		}
+	}
+
+	batch = s.NewBatch()
+	batch.Delete(batchKey1)
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(batchKey1); err != ErrNodeNotFound {
+		t.Errorf("expected: %v, actual: %v", ErrNodeNotFound, err)
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, newMemoryStorage())
+}
+
+func TestLevelDBStorage(t *testing.T) {
+	s, err := NewLevelDBStorage(filepath.Join(t.TempDir(), "leveldb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	testStorage(t, s)
+}
+
+func TestNodeKey(t *testing.T) {
+	if bytes.Equal(nodeKey(0, 0), nodeKey(0, 1)) {
+		t.Errorf("expected different keys for different indices")
+	}
+	if bytes.Equal(nodeKey(0, 0), nodeKey(1, 0)) {
+		t.Errorf("expected different keys for different levels")
+	}
+}
+
+func TestGetNodeSparseFallback(t *testing.T) {
+	config, err := NewConfig(SHA256Hasher{}, 2, 32, WithSparseMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := &Tree{config: config}
+
+	node, err := tree.getNode(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(node.b, config.emptyHashes[0]) {
+		t.Errorf("expected empty-subtree hash for an unset sparse slot")
+	}
+}
@@ -0,0 +1,180 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+)
+
+var (
+	ErrTooManyLeaves          = fmt.Errorf("too many leaves")
+	ErrLeafIndexOutOfRange    = fmt.Errorf("leaf index out of range")
+	ErrInvalidMembershipProof = fmt.Errorf("invalid membership proof")
+)
+
+// newTreeBatchSize bounds how many nodes NewTree buffers in a single Batch
+// before flushing it to config.storage, so that building a tree of depth up
+// to DepthMax holds a bounded amount of memory rather than one batch sized
+// to an entire level.
+const newTreeBatchSize = 1 << 16
+
+// Tree is a fixed-depth binary Merkle tree whose nodes are persisted to
+// config.storage, keyed by (level, index).
+type Tree struct {
+	config    *Config
+	leavesNum uint64
+	root      *Node
+}
+
+// NewTree builds a Tree of depth config.depth from leaves, padding any
+// unused leaf slot with a zero-filled hash-sized buffer. It streams each
+// level's hashes directly into config.storage rather than holding the tree
+// in memory, computing every level above the leaves by reading its children
+// back from config.storage, so that a tree of depth up to DepthMax never
+// keeps a same-size slice of *Node around.
+//
+// In sparse mode, leaves must be empty: unset slots are never written to
+// config.storage, so the tree starts out as the precomputed empty root and
+// is populated later through SetLeafAt.
+func NewTree(config *Config, leaves [][]byte) (*Tree, error) {
+	if uint64(len(leaves)) > config.allLeavesNum {
+		return nil, ErrTooManyLeaves
+	}
+	if config.sparse && len(leaves) > 0 {
+		return nil, ErrSparseLeavesNotEmpty
+	}
+
+	t := &Tree{
+		config:    config,
+		leavesNum: uint64(len(leaves)),
+	}
+
+	if config.sparse {
+		t.root = &Node{b: config.emptyHashes[config.depth]}
+		return t, nil
+	}
+
+	batch := config.storage.NewBatch()
+	for i := uint64(0); i < config.allLeavesNum; i++ {
+		leaf := make([]byte, config.hashSize)
+		if i < uint64(len(leaves)) {
+			leaf = leaves[i]
+		}
+
+		batch.Put(nodeKey(0, i), config.hashLeaf(leaf))
+
+		if (i+1)%newTreeBatchSize == 0 || i == config.allLeavesNum-1 {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch = config.storage.NewBatch()
+		}
+	}
+
+	levelLen := config.allLeavesNum
+	for level := uint64(0); level < config.depth; level++ {
+		parentLen := levelLen / 2
+
+		batch := config.storage.NewBatch()
+		for i := uint64(0); i < parentLen; i++ {
+			left, err := config.storage.Get(nodeKey(level, 2*i))
+			if err != nil {
+				return nil, err
+			}
+			right, err := config.storage.Get(nodeKey(level, 2*i+1))
+			if err != nil {
+				return nil, err
+			}
+
+			batch.Put(nodeKey(level+1, i), config.hashChildren(left, right))
+
+			if (i+1)%newTreeBatchSize == 0 || i == parentLen-1 {
+				if err := batch.Write(); err != nil {
+					return nil, err
+				}
+				batch = config.storage.NewBatch()
+			}
+		}
+
+		levelLen = parentLen
+	}
+
+	root, err := t.getNode(config.depth, 0)
+	if err != nil {
+		return nil, err
+	}
+	root.left, err = t.getNode(config.depth-1, 0)
+	if err != nil {
+		return nil, err
+	}
+	root.right, err = t.getNode(config.depth-1, 1)
+	if err != nil {
+		return nil, err
+	}
+	t.root = root
+
+	return t, nil
+}
+
+// Root returns the tree's root node.
+func (t *Tree) Root() *Node {
+	return t.root
+}
+
+// CreateMembershipProof returns a proof that the leaf at index is included
+// in the tree, as the concatenation of the sibling hash at each level from
+// the leaf up to the root.
+func (t *Tree) CreateMembershipProof(index int) ([]byte, error) {
+	if index < 0 || uint64(index) >= t.config.allLeavesNum {
+		return nil, ErrLeafIndexOutOfRange
+	}
+
+	proof := make([]byte, 0, t.config.depth*t.config.hashSize)
+
+	idx := uint64(index)
+	for level := uint64(0); level < t.config.depth; level++ {
+		sibling, err := t.getNode(level, idx^1)
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, sibling.b...)
+
+		idx /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifyMembershipProof checks that the leaf at index is included in the
+// tree rooted at t.Root(), using proof as produced by
+// CreateMembershipProof.
+func (t *Tree) VerifyMembershipProof(index int, proof []byte) (bool, error) {
+	if index < 0 || uint64(index) >= t.config.allLeavesNum {
+		return false, ErrLeafIndexOutOfRange
+	}
+	if uint64(len(proof)) != t.config.depth*t.config.hashSize {
+		return false, ErrInvalidMembershipProof
+	}
+
+	leaf, err := t.getNode(0, uint64(index))
+	if err != nil {
+		return false, err
+	}
+
+	cur := leaf.b
+	idx := uint64(index)
+	for level := uint64(0); level < t.config.depth; level++ {
+		sibling := proof[level*t.config.hashSize : (level+1)*t.config.hashSize]
+
+		var left, right []byte
+		if idx%2 == 0 {
+			left, right = cur, sibling
+		} else {
+			left, right = sibling, cur
+		}
+		cur = t.config.hashChildren(left, right)
+
+		idx /= 2
+	}
+
+	return bytes.Equal(cur, t.root.b), nil
+}
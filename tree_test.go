@@ -2,16 +2,20 @@ package merkle
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"testing"
 )
 
-var testConfig = &Config{
-	hasher:       sha256.New(),
-	depth:        2,
-	hashSize:     32,
-	allLeavesNum: 4,
-	allNodesNum:  7,
+// newTestConfig returns a fresh Config backed by its own memoryStorage, so
+// that tests writing to it can't observe each other's nodes.
+func newTestConfig() *Config {
+	return &Config{
+		hasher:       SHA256Hasher{},
+		depth:        2,
+		hashSize:     32,
+		allLeavesNum: 4,
+		allNodesNum:  7,
+		storage:      newMemoryStorage(),
+	}
 }
 
 func TestNewTree(t *testing.T) {
@@ -31,7 +35,7 @@ func TestNewTree(t *testing.T) {
 		{
 			"success",
 			input{
-				testConfig,
+				newTestConfig(),
 				[][]byte{
 					[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
 					[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
@@ -69,7 +73,7 @@ func TestNewTree(t *testing.T) {
 		{
 			"failure: too many leaves",
 			input{
-				testConfig,
+				newTestConfig(),
 				[][]byte{nil, nil, nil, nil, nil},
 			},
 			output{
@@ -102,7 +106,7 @@ func TestNewTree(t *testing.T) {
 
 func TestMembershipProof(t *testing.T) {
 	tree, err := NewTree(
-		testConfig,
+		newTestConfig(),
 		[][]byte{
 			[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
 			[]byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
@@ -169,8 +173,8 @@ func TestMembershipProof(t *testing.T) {
 			}
 
 			if len(proof) > 0 {
-				for j := 0; j <= tree.config.allLeavesNum; j++ {
-					ok, err := tree.VerifyMembershipProof(j, proof)
+				for j := uint64(0); j <= tree.config.allLeavesNum; j++ {
+					ok, err := tree.VerifyMembershipProof(int(j), proof)
 					if err != nil {
 						if j < tree.config.allLeavesNum {
 							t.Fatal(err)
@@ -178,9 +182,9 @@ func TestMembershipProof(t *testing.T) {
 							t.Fatal(err)
 						}
 					}
-					if j == in.index && !ok {
+					if j == uint64(in.index) && !ok {
 						t.Errorf("expected: %t, actual: %t", true, ok)
-					} else if j != in.index && ok {
+					} else if j != uint64(in.index) && ok {
 						t.Errorf("expected: %t, actual: %t", false, ok)
 					}
 				}